@@ -0,0 +1,86 @@
+// Package config loads the repository allowlist that governs which
+// pushes are allowed to trigger a deploy.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RepoConfig declares deploy policy for a single repository: which
+// refs are allowed to trigger a deploy, and optional per-repo
+// overrides for the webhook secret and deploy script path.
+type RepoConfig struct {
+	Name       string   `yaml:"name"`
+	Refs       []string `yaml:"refs"`
+	Secret     string   `yaml:"secret,omitempty"`
+	ScriptPath string   `yaml:"script_path,omitempty"`
+}
+
+// Config is the allowlist of repositories the deployer will act on,
+// loaded from a YAML file (by convention /app/config.yaml).
+type Config struct {
+	Repositories []RepoConfig `yaml:"repositories"`
+
+	byName map[string]RepoConfig
+}
+
+// Load reads and parses a Config from path.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+
+	cfg.byName = make(map[string]RepoConfig, len(cfg.Repositories))
+	for _, repo := range cfg.Repositories {
+		cfg.byName[repo.Name] = repo
+	}
+
+	return &cfg, nil
+}
+
+// Allowed reports whether repository is allowlisted and ref matches
+// one of its configured glob patterns, e.g. "refs/heads/main",
+// "refs/heads/release/*", or "refs/tags/v*".
+func (c *Config) Allowed(repository, ref string) bool {
+	repo, ok := c.byName[repository]
+	if !ok {
+		return false
+	}
+
+	for _, pattern := range repo.Refs {
+		if matched, err := path.Match(pattern, ref); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// SecretOverride returns the per-repo webhook secret override for
+// repository, if one is configured.
+func (c *Config) SecretOverride(repository string) (string, bool) {
+	repo, ok := c.byName[repository]
+	if !ok || repo.Secret == "" {
+		return "", false
+	}
+	return repo.Secret, true
+}
+
+// ScriptPath returns the per-repo deploy script path override for
+// repository, if one is configured.
+func (c *Config) ScriptPath(repository string) (string, bool) {
+	repo, ok := c.byName[repository]
+	if !ok || repo.ScriptPath == "" {
+		return "", false
+	}
+	return repo.ScriptPath, true
+}
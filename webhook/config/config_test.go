@@ -0,0 +1,73 @@
+package config
+
+import "testing"
+
+func newConfig(repos ...RepoConfig) *Config {
+	cfg := &Config{Repositories: repos, byName: make(map[string]RepoConfig, len(repos))}
+	for _, repo := range repos {
+		cfg.byName[repo.Name] = repo
+	}
+	return cfg
+}
+
+func TestConfigAllowed(t *testing.T) {
+	cfg := newConfig(
+		RepoConfig{Name: "myrepo", Refs: []string{"refs/heads/main", "refs/heads/release/*", "refs/tags/v*"}},
+	)
+
+	tests := []struct {
+		name       string
+		repository string
+		ref        string
+		want       bool
+	}{
+		{name: "exact branch match", repository: "myrepo", ref: "refs/heads/main", want: true},
+		{name: "glob branch match", repository: "myrepo", ref: "refs/heads/release/1.2", want: true},
+		{name: "glob tag match", repository: "myrepo", ref: "refs/tags/v1.0.0", want: true},
+		{name: "unmatched ref", repository: "myrepo", ref: "refs/heads/feature/x", want: false},
+		{name: "glob doesn't cross slash boundaries", repository: "myrepo", ref: "refs/heads/release/1/2", want: false},
+		{name: "repository not allowlisted", repository: "other", ref: "refs/heads/main", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cfg.Allowed(tt.repository, tt.ref); got != tt.want {
+				t.Errorf("Allowed(%q, %q) = %v, want %v", tt.repository, tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigSecretOverride(t *testing.T) {
+	cfg := newConfig(
+		RepoConfig{Name: "withsecret", Secret: "s3cret"},
+		RepoConfig{Name: "nosecret"},
+	)
+
+	if secret, ok := cfg.SecretOverride("withsecret"); !ok || secret != "s3cret" {
+		t.Errorf("SecretOverride(withsecret) = %q, %v, want %q, true", secret, ok, "s3cret")
+	}
+	if _, ok := cfg.SecretOverride("nosecret"); ok {
+		t.Errorf("SecretOverride(nosecret) ok = true, want false")
+	}
+	if _, ok := cfg.SecretOverride("missing"); ok {
+		t.Errorf("SecretOverride(missing) ok = true, want false")
+	}
+}
+
+func TestConfigScriptPath(t *testing.T) {
+	cfg := newConfig(
+		RepoConfig{Name: "withoverride", ScriptPath: "/custom/deploy.sh"},
+		RepoConfig{Name: "nooverride"},
+	)
+
+	if path, ok := cfg.ScriptPath("withoverride"); !ok || path != "/custom/deploy.sh" {
+		t.Errorf("ScriptPath(withoverride) = %q, %v, want %q, true", path, ok, "/custom/deploy.sh")
+	}
+	if _, ok := cfg.ScriptPath("nooverride"); ok {
+		t.Errorf("ScriptPath(nooverride) ok = true, want false")
+	}
+	if _, ok := cfg.ScriptPath("missing"); ok {
+		t.Errorf("ScriptPath(missing) ok = true, want false")
+	}
+}
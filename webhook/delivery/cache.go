@@ -0,0 +1,101 @@
+// Package delivery tracks which webhook deliveries have already been
+// processed, so GitHub's (and other providers') automatic retries of
+// an already-accepted delivery don't trigger a second deploy of the
+// same push.
+package delivery
+
+import (
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var bucketName = []byte("deliveries")
+
+// Cache records delivery IDs on disk so the dedup window survives a
+// restart, with entries expiring after TTL.
+type Cache struct {
+	db  *bolt.DB
+	ttl time.Duration
+}
+
+// Open opens (creating if necessary) a bolt-backed cache at path.
+func Open(path string, ttl time.Duration) (*Cache, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("opening delivery cache: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketName)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("initializing delivery cache bucket: %w", err)
+	}
+
+	return &Cache{db: db, ttl: ttl}, nil
+}
+
+// Close releases the underlying bolt database file.
+func (c *Cache) Close() error {
+	return c.db.Close()
+}
+
+// SeenBefore records key as processed and reports whether it had
+// already been recorded within the TTL window, i.e. whether this
+// delivery is a duplicate/replay rather than a new one.
+func (c *Cache) SeenBefore(key string) (bool, error) {
+	now := time.Now()
+	duplicate := false
+
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketName)
+
+		if raw := b.Get([]byte(key)); raw != nil {
+			if seenAt, err := time.Parse(time.RFC3339Nano, string(raw)); err == nil && now.Sub(seenAt) < c.ttl {
+				duplicate = true
+				return nil
+			}
+		}
+
+		return b.Put([]byte(key), []byte(now.Format(time.RFC3339Nano)))
+	})
+	if err != nil {
+		return false, fmt.Errorf("checking delivery cache: %w", err)
+	}
+
+	return duplicate, nil
+}
+
+// Sweep deletes entries older than the TTL, bounding the cache's disk
+// footprint. Intended to be called periodically from a background
+// goroutine.
+func (c *Cache) Sweep() error {
+	cutoff := time.Now().Add(-c.ttl)
+
+	return c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketName)
+
+		var stale [][]byte
+		err := b.ForEach(func(k, v []byte) error {
+			seenAt, err := time.Parse(time.RFC3339Nano, string(v))
+			if err != nil || seenAt.Before(cutoff) {
+				stale = append(stale, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range stale {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
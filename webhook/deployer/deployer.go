@@ -0,0 +1,171 @@
+// Package deployer executes a repository's deploy script and
+// captures its output to a log file that operators can tail live.
+package deployer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"webhook/config"
+	"webhook/deployqueue"
+)
+
+// Executor runs the deploy script for a repository, looked up from
+// ScriptsDir/<repo>.sh (or a per-repo override from Config),
+// enforcing Timeout and capturing combined stdout/stderr under
+// LogsDir/<repo>/<timestamp>.log.
+type Executor struct {
+	ScriptsDir string
+	LogsDir    string
+	Timeout    time.Duration
+	Config     *config.Config
+}
+
+// New returns an Executor configured with the given scripts
+// directory, logs directory, per-deploy timeout, and repository
+// config (for per-repo script path overrides).
+func New(scriptsDir, logsDir string, timeout time.Duration, cfg *config.Config) *Executor {
+	return &Executor{ScriptsDir: scriptsDir, LogsDir: logsDir, Timeout: timeout, Config: cfg}
+}
+
+// Run looks up and executes the deploy script for job.Event.Repository,
+// recording its log path and exit code on job. It satisfies
+// deployqueue.Handler.
+func (e *Executor) Run(ctx context.Context, job *deployqueue.Job) error {
+	event := job.Event
+
+	scriptPath, err := e.scriptPath(event.Repository)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(scriptPath); err != nil {
+		return fmt.Errorf("deploy script not found: %w", err)
+	}
+
+	logPath, logFile, err := e.openLogFile(event.Repository)
+	if err != nil {
+		return fmt.Errorf("opening deploy log: %w", err)
+	}
+	defer logFile.Close()
+	job.SetLogPath(logPath)
+
+	ctx, cancel := context.WithTimeout(ctx, e.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, scriptPath)
+	cmd.Env = append(os.Environ(),
+		"DEPLOY_REPO="+event.Repository,
+		"DEPLOY_REF="+event.Ref,
+		"DEPLOY_COMMIT="+event.CommitSHA,
+		"DEPLOY_PUSHER="+event.Pusher,
+		"DEPLOY_PROVIDER="+event.Provider,
+	)
+
+	output := io.MultiWriter(logFile, &lineTee{job: job})
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	runErr := cmd.Run()
+	if cmd.ProcessState != nil {
+		job.SetExitCode(cmd.ProcessState.ExitCode())
+	} else {
+		job.SetExitCode(-1)
+	}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("deploy script timed out after %s", e.Timeout)
+	}
+	if runErr != nil {
+		return fmt.Errorf("deploy script failed: %w", runErr)
+	}
+	return nil
+}
+
+// scriptPath resolves the deploy script for repository, preferring a
+// per-repo override from Config if one is configured. Absent an
+// override, it verifies the resolved path stays within ScriptsDir,
+// rejecting any attempt at path traversal via the repository name.
+func (e *Executor) scriptPath(repository string) (string, error) {
+	if e.Config != nil {
+		if override, ok := e.Config.ScriptPath(repository); ok {
+			return filepath.Abs(override)
+		}
+	}
+
+	scriptsDir, err := filepath.Abs(e.ScriptsDir)
+	if err != nil {
+		return "", fmt.Errorf("resolving scripts directory: %w", err)
+	}
+
+	path := filepath.Join(scriptsDir, repository+".sh")
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("resolving script path: %w", err)
+	}
+
+	if path != scriptsDir && !strings.HasPrefix(path, scriptsDir+string(filepath.Separator)) {
+		return "", fmt.Errorf("script path escapes scripts directory: %s", repository)
+	}
+
+	return path, nil
+}
+
+// openLogFile creates (if needed) and opens the log file for
+// repository's deploy under LogsDir, verifying the resolved directory
+// stays within LogsDir, the same guard scriptPath applies against
+// path traversal via the repository name.
+func (e *Executor) openLogFile(repository string) (string, *os.File, error) {
+	logsDir, err := filepath.Abs(e.LogsDir)
+	if err != nil {
+		return "", nil, fmt.Errorf("resolving logs directory: %w", err)
+	}
+
+	repoLogDir := filepath.Join(logsDir, repository)
+	repoLogDir, err = filepath.Abs(repoLogDir)
+	if err != nil {
+		return "", nil, fmt.Errorf("resolving repo log directory: %w", err)
+	}
+
+	if repoLogDir != logsDir && !strings.HasPrefix(repoLogDir, logsDir+string(filepath.Separator)) {
+		return "", nil, fmt.Errorf("log path escapes logs directory: %s", repository)
+	}
+
+	if err := os.MkdirAll(repoLogDir, 0755); err != nil {
+		return "", nil, err
+	}
+
+	path := filepath.Join(repoLogDir, fmt.Sprintf("%d.log", time.Now().UnixNano()))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", nil, err
+	}
+	return path, f, nil
+}
+
+// lineTee splits written bytes into lines and appends each one to the
+// job's in-memory log tail, so /jobs/{id} reflects live progress
+// without re-reading the log file from disk.
+type lineTee struct {
+	job *deployqueue.Job
+	buf []byte
+}
+
+func (t *lineTee) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	for {
+		i := bytes.IndexByte(t.buf, '\n')
+		if i < 0 {
+			break
+		}
+		t.job.AppendLog(string(t.buf[:i]))
+		t.buf = t.buf[i+1:]
+	}
+	return len(p), nil
+}
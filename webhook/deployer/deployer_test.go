@@ -0,0 +1,96 @@
+package deployer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"webhook/config"
+)
+
+func TestExecutorScriptPath(t *testing.T) {
+	scriptsDir := "/scripts"
+
+	tests := []struct {
+		name       string
+		repository string
+		wantPath   string
+		wantErr    bool
+	}{
+		{name: "normal repository", repository: "myrepo", wantPath: filepath.Join(scriptsDir, "myrepo.sh")},
+		{name: "path traversal via ..", repository: "../../etc/passwd", wantErr: true},
+		{name: "path traversal via embedded ..", repository: "foo/../../bar", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Executor{ScriptsDir: scriptsDir}
+			path, err := e.scriptPath(tt.repository)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("scriptPath(%q) error = nil, want error", tt.repository)
+				}
+				if !strings.Contains(err.Error(), "escapes scripts directory") {
+					t.Errorf("scriptPath(%q) error = %v, want escape error", tt.repository, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("scriptPath(%q) error = %v", tt.repository, err)
+			}
+			if path != tt.wantPath {
+				t.Errorf("scriptPath(%q) = %q, want %q", tt.repository, path, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestExecutorScriptPathPrefersConfigOverride(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	yaml := "repositories:\n  - name: myrepo\n    script_path: /custom/deploy.sh\n"
+	if err := os.WriteFile(configPath, []byte(yaml), 0644); err != nil {
+		t.Fatalf("writing config: %v", err)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+
+	e := &Executor{ScriptsDir: "/scripts", Config: cfg}
+	path, err := e.scriptPath("myrepo")
+	if err != nil {
+		t.Fatalf("scriptPath() error = %v", err)
+	}
+	if path != "/custom/deploy.sh" {
+		t.Errorf("scriptPath() = %q, want /custom/deploy.sh (the configured override)", path)
+	}
+}
+
+func TestExecutorOpenLogFileRejectsPathTraversal(t *testing.T) {
+	e := &Executor{LogsDir: t.TempDir()}
+
+	if _, _, err := e.openLogFile("../../etc"); err == nil {
+		t.Errorf("openLogFile() error = nil, want error for traversal attempt")
+	} else if !strings.Contains(err.Error(), "escapes logs directory") {
+		t.Errorf("openLogFile() error = %v, want escape error", err)
+	}
+}
+
+func TestExecutorOpenLogFileWritesUnderLogsDir(t *testing.T) {
+	dir := t.TempDir()
+	e := &Executor{LogsDir: dir}
+
+	path, f, err := e.openLogFile("myrepo")
+	if err != nil {
+		t.Fatalf("openLogFile() error = %v", err)
+	}
+	defer f.Close()
+
+	if !strings.HasPrefix(path, filepath.Join(dir, "myrepo")+string(filepath.Separator)) {
+		t.Errorf("openLogFile() path = %q, want under %q", path, filepath.Join(dir, "myrepo"))
+	}
+}
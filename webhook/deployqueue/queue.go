@@ -0,0 +1,363 @@
+// Package deployqueue provides a bounded, persistent FIFO queue of
+// deploy jobs drained by a worker pool, with per-repository
+// serialization so two pushes to the same repo never deploy
+// concurrently while different repos deploy in parallel.
+package deployqueue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"webhook/webhooks"
+)
+
+// Status is the lifecycle state of a Job.
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// maxLogLines bounds how many trailing log lines a Job keeps in
+// memory for the /jobs/{id} status endpoint.
+const maxLogLines = 200
+
+// Handler executes a queued job's deploy. It should append progress
+// to job via AppendLog and set the process exit code via SetExitCode
+// before returning. A non-nil error marks the job failed.
+type Handler func(ctx context.Context, job *Job) error
+
+// Job tracks a single deploy from enqueue through completion.
+type Job struct {
+	ID         string              `json:"id"`
+	Event      *webhooks.PushEvent `json:"event"`
+	EnqueuedAt time.Time           `json:"enqueued_at"`
+
+	mu         sync.Mutex
+	status     Status
+	exitCode   int
+	logPath    string
+	startedAt  time.Time
+	finishedAt time.Time
+	logs       []string
+}
+
+// JobView is the JSON-serializable snapshot returned by the /jobs
+// endpoints.
+type JobView struct {
+	ID         string              `json:"id"`
+	Event      *webhooks.PushEvent `json:"event"`
+	Status     Status              `json:"status"`
+	ExitCode   int                 `json:"exit_code"`
+	EnqueuedAt time.Time           `json:"enqueued_at"`
+	StartedAt  time.Time           `json:"started_at,omitempty"`
+	FinishedAt time.Time           `json:"finished_at,omitempty"`
+	Duration   string              `json:"duration,omitempty"`
+	LogPath    string              `json:"log_path,omitempty"`
+	Logs       []string            `json:"logs"`
+}
+
+// AppendLog adds a line to the job's log tail, dropping the oldest
+// line once maxLogLines is exceeded.
+func (j *Job) AppendLog(line string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.logs = append(j.logs, line)
+	if len(j.logs) > maxLogLines {
+		j.logs = j.logs[len(j.logs)-maxLogLines:]
+	}
+}
+
+// SetExitCode records the deploy script's process exit code.
+func (j *Job) SetExitCode(code int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.exitCode = code
+}
+
+// SetLogPath records where the deploy script's captured output was
+// written, so it can be located later by the log-streaming endpoint.
+func (j *Job) SetLogPath(path string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.logPath = path
+}
+
+// LogPath returns where the deploy script's captured output was
+// written, or "" if it hasn't started yet.
+func (j *Job) LogPath() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.logPath
+}
+
+func (j *Job) setStatus(status Status) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.status = status
+}
+
+// View returns a JSON-serializable snapshot of the job's current
+// state.
+func (j *Job) View() JobView {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	view := JobView{
+		ID:         j.ID,
+		Event:      j.Event,
+		Status:     j.status,
+		ExitCode:   j.exitCode,
+		EnqueuedAt: j.EnqueuedAt,
+		StartedAt:  j.startedAt,
+		FinishedAt: j.finishedAt,
+		LogPath:    j.logPath,
+		Logs:       append([]string(nil), j.logs...),
+	}
+	if !j.startedAt.IsZero() && !j.finishedAt.IsZero() {
+		view.Duration = j.finishedAt.Sub(j.startedAt).String()
+	}
+	return view
+}
+
+var jobCounter uint64
+
+func newJob(event *webhooks.PushEvent) *Job {
+	seq := atomic.AddUint64(&jobCounter, 1)
+	return &Job{
+		ID:         fmt.Sprintf("%d-%d", time.Now().UnixNano(), seq),
+		Event:      event,
+		EnqueuedAt: time.Now(),
+		status:     StatusQueued,
+	}
+}
+
+// ErrQueueFull is returned by Enqueue when the queue is at capacity.
+var ErrQueueFull = fmt.Errorf("deploy queue is full")
+
+// Queue is a bounded FIFO of deploy jobs, drained by a pool of
+// workers that serialize deploys per repository.
+type Queue struct {
+	dir     string
+	handler Handler
+	jobs    chan *Job
+
+	mu       sync.Mutex
+	byID     map[string]*Job
+	order    []string
+	repoLock map[string]*sync.Mutex
+}
+
+// New creates a queue backed by dir for job persistence, with the
+// given bounded size and handler. It does not start workers or
+// resume persisted jobs; call Start for that.
+func New(dir string, size int, handler Handler) (*Queue, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating queue directory: %w", err)
+	}
+
+	return &Queue{
+		dir:      dir,
+		handler:  handler,
+		jobs:     make(chan *Job, size),
+		byID:     make(map[string]*Job),
+		repoLock: make(map[string]*sync.Mutex),
+	}, nil
+}
+
+// Start launches workers workers to drain the queue, after first
+// resuming any jobs persisted from a previous run (e.g. one ended by
+// a crash or restart).
+func (q *Queue) Start(workers int) error {
+	if err := q.resume(); err != nil {
+		return err
+	}
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+	return nil
+}
+
+// Enqueue adds a new job for event to the queue, persisting it so it
+// survives a restart before the worker pool picks it up.
+func (q *Queue) Enqueue(event *webhooks.PushEvent) (*Job, error) {
+	job := newJob(event)
+
+	if err := q.persist(job); err != nil {
+		fmt.Printf("[DEPLOYQUEUE] Failed to persist job %s: %v\n", job.ID, err)
+	}
+
+	q.mu.Lock()
+	q.byID[job.ID] = job
+	q.order = append(q.order, job.ID)
+	q.mu.Unlock()
+
+	select {
+	case q.jobs <- job:
+	default:
+		q.unregister(job.ID)
+		if err := q.removePersisted(job.ID); err != nil {
+			fmt.Printf("[DEPLOYQUEUE] Failed to remove persisted job %s: %v\n", job.ID, err)
+		}
+		return nil, ErrQueueFull
+	}
+
+	return job, nil
+}
+
+// unregister removes id from byID and order, undoing a registration
+// made in anticipation of a channel send that turned out to fail
+// (e.g. the queue was full).
+func (q *Queue) unregister(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.byID, id)
+	for i, existing := range q.order {
+		if existing == id {
+			q.order = append(q.order[:i], q.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// Get returns the job registered under id, if any.
+func (q *Queue) Get(id string) (*Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.byID[id]
+	return job, ok
+}
+
+// List returns all known jobs, oldest first.
+func (q *Queue) List() []*Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	jobs := make([]*Job, 0, len(q.order))
+	for _, id := range q.order {
+		jobs = append(jobs, q.byID[id])
+	}
+	return jobs
+}
+
+func (q *Queue) work() {
+	for job := range q.jobs {
+		q.run(job)
+	}
+}
+
+func (q *Queue) run(job *Job) {
+	lock := q.lockFor(job.Event.Repository)
+	lock.Lock()
+	defer lock.Unlock()
+
+	job.setStatus(StatusRunning)
+	job.mu.Lock()
+	job.startedAt = time.Now()
+	job.mu.Unlock()
+
+	err := q.handler(context.Background(), job)
+
+	job.mu.Lock()
+	job.finishedAt = time.Now()
+	job.mu.Unlock()
+
+	if err != nil {
+		job.setStatus(StatusFailed)
+		fmt.Printf("[DEPLOYQUEUE] Job %s failed: %v\n", job.ID, err)
+	} else {
+		job.setStatus(StatusSucceeded)
+	}
+
+	if err := q.removePersisted(job.ID); err != nil {
+		fmt.Printf("[DEPLOYQUEUE] Failed to remove persisted job %s: %v\n", job.ID, err)
+	}
+}
+
+func (q *Queue) lockFor(repository string) *sync.Mutex {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	lock, ok := q.repoLock[repository]
+	if !ok {
+		lock = &sync.Mutex{}
+		q.repoLock[repository] = lock
+	}
+	return lock
+}
+
+func (q *Queue) jobPath(id string) string {
+	return filepath.Join(q.dir, id+".json")
+}
+
+func (q *Queue) persist(job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(q.jobPath(job.ID), data, 0644)
+}
+
+func (q *Queue) removePersisted(id string) error {
+	err := os.Remove(q.jobPath(id))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// resume reloads jobs persisted under dir/*.json from a previous run
+// and re-enqueues them in their original order so a crash or restart
+// doesn't lose pending deploys.
+func (q *Queue) resume() error {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return fmt.Errorf("reading queue directory: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".json" {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(q.dir, name))
+		if err != nil {
+			fmt.Printf("[DEPLOYQUEUE] Failed to read persisted job %s: %v\n", name, err)
+			continue
+		}
+
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil {
+			fmt.Printf("[DEPLOYQUEUE] Failed to parse persisted job %s: %v\n", name, err)
+			continue
+		}
+		job.status = StatusQueued
+
+		select {
+		case q.jobs <- &job:
+			q.mu.Lock()
+			q.byID[job.ID] = &job
+			q.order = append(q.order, job.ID)
+			q.mu.Unlock()
+		default:
+			fmt.Printf("[DEPLOYQUEUE] Queue full, dropping persisted job %s\n", job.ID)
+		}
+	}
+
+	return nil
+}
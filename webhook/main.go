@@ -1,47 +1,127 @@
 package main
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
-	"encoding/json"
+	"bufio"
+	"context"
+	"crypto/subtle"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
-	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"webhook/config"
+	"webhook/delivery"
+	"webhook/deployer"
+	"webhook/deployqueue"
+	"webhook/webhooks"
 )
 
-var (
-	webhookDir      = "/app/webhook_jobs"
-	webhookSecret   string
-	repoNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+const (
+	defaultQueueSize      = 100
+	defaultWorkers        = 4
+	defaultDeployTimeout  = 15 * time.Minute
+	logStreamPollInterval = 500 * time.Millisecond
+	defaultDeliveryTTL    = 10 * time.Minute
+	defaultMaxClockSkew   = 5 * time.Minute
+	deliverySweepInterval = time.Minute
 )
 
-type GitHubWebhookPayload struct {
-	Ref        string `json:"ref"`
-	Repository struct {
-		Name string `json:"name"`
-	} `json:"repository"`
-}
+var (
+	webhookDir   = "/app/webhook_jobs"
+	queue        *deployqueue.Queue
+	executor     *deployer.Executor
+	logsToken    string
+	deliveries   *delivery.Cache
+	maxClockSkew time.Duration
+	cfg          *config.Config
+)
 
 func init() {
-	webhookSecret = os.Getenv("GITHUB_WEBHOOK_SECRET")
-	webhookSecret = strings.Trim(webhookSecret, "' \t\n\r")
-	if webhookSecret == "" {
-		panic("GITHUB_WEBHOOK_SECRET environment variable is required")
-	}
+	webhooks.Register(webhooks.NewGitHubProvider())
+	webhooks.Register(webhooks.NewGiteaProvider())
+	webhooks.Register(webhooks.NewGitLabProvider())
+	webhooks.Register(webhooks.NewBitbucketProvider())
 
 	if err := os.MkdirAll(webhookDir, 0755); err != nil {
 		panic(fmt.Sprintf("Failed to create webhook directory: %v", err))
 	}
+
+	logsToken = strings.Trim(os.Getenv("DEPLOY_LOGS_TOKEN"), "' \t\n\r")
+	if logsToken == "" {
+		panic("DEPLOY_LOGS_TOKEN environment variable is required")
+	}
+
+	var err error
+	cfg, err = config.Load(envString("CONFIG_PATH", "/app/config.yaml"))
+	if err != nil {
+		panic(fmt.Sprintf("Failed to load config: %v", err))
+	}
+
+	executor = deployer.New(
+		envString("DEPLOY_SCRIPTS_DIR", filepath.Join(webhookDir, "scripts")),
+		envString("DEPLOY_LOGS_DIR", filepath.Join(webhookDir, "logs")),
+		envDuration("DEPLOY_TIMEOUT", defaultDeployTimeout),
+		cfg,
+	)
+
+	queue, err = deployqueue.New(filepath.Join(webhookDir, "queue"), envInt("DEPLOY_QUEUE_SIZE", defaultQueueSize), deployHandler)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to create deploy queue: %v", err))
+	}
+
+	deliveryTTL := envDuration("DELIVERY_CACHE_TTL", defaultDeliveryTTL)
+	deliveries, err = delivery.Open(envString("DELIVERY_CACHE_PATH", filepath.Join(webhookDir, "deliveries.db")), deliveryTTL)
+	if err != nil {
+		panic(fmt.Sprintf("Failed to open delivery cache: %v", err))
+	}
+
+	maxClockSkew = envDuration("MAX_CLOCK_SKEW", defaultMaxClockSkew)
+}
+
+func envString(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func envInt(name string, fallback int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return fallback
+	}
+	return n
+}
+
+func envDuration(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
 }
 
 func main() {
+	if err := queue.Start(envInt("DEPLOY_WORKERS", defaultWorkers)); err != nil {
+		panic(fmt.Sprintf("Failed to start deploy queue: %v", err))
+	}
+
+	go sweepDeliveries()
+
 	router := gin.New()
 
 	router.Use(gin.LoggerWithConfig(gin.LoggerConfig{
@@ -54,44 +134,47 @@ func main() {
 	})
 
 	router.HEAD("/health", healthCheckHandler)
-	router.POST("/webhook/github", githubWebhookHandler)
+	router.POST("/webhook/:provider", webhookHandler)
+	router.GET("/jobs", listJobsHandler)
+	router.GET("/jobs/:id", getJobHandler)
+	router.GET("/logs/:repo/:id", streamLogHandler)
 
 	router.Run(":8000")
 }
 
+// sweepDeliveries periodically evicts expired entries from the
+// delivery cache so its on-disk footprint doesn't grow unbounded.
+func sweepDeliveries() {
+	ticker := time.NewTicker(deliverySweepInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := deliveries.Sweep(); err != nil {
+			fmt.Printf("[DELIVERY] Sweep failed: %v\n", err)
+		}
+	}
+}
+
 func healthCheckHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"status": "healthy",
 	})
 }
 
-func verifySignature(body []byte, signature string) error {
-	if signature == "" || len(signature) < 7 || signature[:7] != "sha256=" {
-		return fmt.Errorf("missing or invalid signature")
-	}
-	mac := hmac.New(sha256.New, []byte(webhookSecret))
-	mac.Write(body)
-	expectedMAC := hex.EncodeToString(mac.Sum(nil))
-	receivedMAC := signature[7:] // Remove "sha256=" prefix
-
-	if !hmac.Equal([]byte(expectedMAC), []byte(receivedMAC)) {
-		return fmt.Errorf("signature mismatch")
-	}
-
-	return nil
+// deployHandler is the deployqueue.Handler that actually performs a
+// deploy for a queued job.
+func deployHandler(ctx context.Context, job *deployqueue.Job) error {
+	return executor.Run(ctx, job)
 }
 
-func runDeploy(repository string) {
-	repoDir := filepath.Join(webhookDir, repository)
-	if err := os.MkdirAll(repoDir, 0755); err != nil {
-		fmt.Printf("Error creating repository directory: %v\n", err)
+func webhookHandler(c *gin.Context) {
+	providerName := c.Param("provider")
+	provider, ok := webhooks.Get(providerName)
+	if !ok {
+		fmt.Printf("[WEBHOOK] Unknown provider: %s\n", providerName)
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown provider"})
 		return
 	}
 
-	fmt.Printf("Deploy triggered for repository: %s\n", repository)
-}
-
-func githubWebhookHandler(c *gin.Context) {
 	body, err := io.ReadAll(c.Request.Body)
 	if err != nil {
 		fmt.Printf("[WEBHOOK] Failed to read body: %v\n", err)
@@ -99,44 +182,137 @@ func githubWebhookHandler(c *gin.Context) {
 		return
 	}
 
-	signature := c.GetHeader("X-Hub-Signature-256")
-	if err := verifySignature(body, signature); err != nil {
-		fmt.Printf("[WEBHOOK] Signature failed: %v | sig_present: %v | body_len: %d\n",
-			err, signature != "", len(body))
+	// Parse before Verify so the repository name (needed to look up a
+	// per-repo secret override from config) is known before checking
+	// the signature. Parse only decodes JSON and reads headers, so it's
+	// safe to run against an unauthenticated body.
+	event, err := provider.Parse(body, c.Request.Header)
+	if err != nil {
+		fmt.Printf("[WEBHOOK] %v | provider: %s\n", err, providerName)
+		c.JSON(http.StatusOK, gin.H{"ok": true, "ignored": err.Error()})
+		return
+	}
+
+	secretOverride, _ := cfg.SecretOverride(event.Repository)
+	if err := provider.Verify(c.Request, body, secretOverride); err != nil {
+		fmt.Printf("[WEBHOOK] Signature failed: %v | provider: %s | body_len: %d\n",
+			err, providerName, len(body))
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
 
-	event := c.GetHeader("X-GitHub-Event")
-	if event != "push" {
-		fmt.Printf("[WEBHOOK] Ignored event type: %s\n", event)
-		c.JSON(http.StatusOK, gin.H{"ok": true, "ignored": "not a push"})
+	if deliveryID := provider.DeliveryID(c.Request.Header); deliveryID != "" {
+		duplicate, err := deliveries.SeenBefore(providerName + ":" + deliveryID)
+		if err != nil {
+			fmt.Printf("[WEBHOOK] Delivery cache error: %v | provider: %s\n", err, providerName)
+		} else if duplicate {
+			fmt.Printf("[WEBHOOK] Ignored duplicate delivery: %s | provider: %s\n", deliveryID, providerName)
+			c.JSON(http.StatusOK, gin.H{"ok": true, "ignored": "duplicate"})
+			return
+		}
+	}
+
+	if !event.Timestamp.IsZero() {
+		if skew := time.Since(event.Timestamp); skew < -maxClockSkew || skew > maxClockSkew {
+			fmt.Printf("[WEBHOOK] Rejected commit timestamp outside clock skew: %s | repo: %s | skew: %s\n",
+				event.Timestamp, event.Repository, skew)
+			c.JSON(http.StatusOK, gin.H{"ok": true, "ignored": "timestamp outside allowed clock skew"})
+			return
+		}
+	}
+
+	if !cfg.Allowed(event.Repository, event.Ref) {
+		fmt.Printf("[WEBHOOK] Repository/ref not allowlisted: %s @ %s\n", event.Repository, event.Ref)
+		c.JSON(http.StatusOK, gin.H{"ok": true, "ignored": "repository or ref not allowlisted"})
 		return
 	}
 
-	var payload GitHubWebhookPayload
-	if err := json.Unmarshal(body, &payload); err != nil {
-		fmt.Printf("[WEBHOOK] Invalid JSON: %v\n", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
+	job, err := queue.Enqueue(event)
+	if err != nil {
+		fmt.Printf("[WEBHOOK] Failed to queue deploy: %v | repo: %s\n", err, event.Repository)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
 		return
 	}
 
-	if payload.Ref != "refs/heads/main" {
-		fmt.Printf("[WEBHOOK] Ignored ref: %s | repo: %s\n", payload.Ref, payload.Repository.Name)
-		c.JSON(http.StatusOK, gin.H{"ok": true, "ignored": "not main"})
+	fmt.Printf("[WEBHOOK] Deploy queued: %s (provider: %s, job: %s)\n", event.Repository, event.Provider, job.ID)
+
+	c.JSON(http.StatusOK, gin.H{"ok": true, "queued": true, "job_id": job.ID})
+}
+
+func listJobsHandler(c *gin.Context) {
+	jobs := queue.List()
+	views := make([]deployqueue.JobView, 0, len(jobs))
+	for _, job := range jobs {
+		views = append(views, job.View())
+	}
+	c.JSON(http.StatusOK, gin.H{"jobs": views})
+}
+
+func getJobHandler(c *gin.Context) {
+	job, ok := queue.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
 		return
 	}
+	c.JSON(http.StatusOK, job.View())
+}
 
-	repository := payload.Repository.Name
-	if !repoNamePattern.MatchString(repository) {
-		fmt.Printf("[WEBHOOK] Invalid or empty repo name: %q\n", repository)
-		c.JSON(http.StatusOK, gin.H{"ok": true, "ignored": "invalid repository"})
+// streamLogHandler streams a deploy job's log file live via
+// server-sent events, polling for new content while the job runs.
+func streamLogHandler(c *gin.Context) {
+	token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(logsToken)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing or invalid bearer token"})
 		return
 	}
 
-	fmt.Printf("[WEBHOOK] Deploy queued: %s\n", repository)
+	job, ok := queue.Get(c.Param("id"))
+	if !ok || job.Event.Repository != c.Param("repo") {
+		c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		return
+	}
 
-	go runDeploy(repository)
+	logPath := job.LogPath()
+	if logPath == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "log not available yet"})
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{"ok": true, "queued": true})
+	f, err := os.Open(logPath)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to open log"})
+		return
+	}
+	defer f.Close()
+	reader := bufio.NewReader(f)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	c.Stream(func(w io.Writer) bool {
+		for {
+			line, err := reader.ReadString('\n')
+			if line != "" {
+				fmt.Fprintf(w, "data: %s\n\n", strings.TrimRight(line, "\n"))
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return false
+			}
+		}
+
+		if terminal := job.View().Status; terminal == deployqueue.StatusSucceeded || terminal == deployqueue.StatusFailed {
+			return false
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-time.After(logStreamPollInterval):
+			return true
+		}
+	})
 }
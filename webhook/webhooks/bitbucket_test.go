@@ -0,0 +1,111 @@
+package webhooks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBitbucketProviderVerify(t *testing.T) {
+	tests := []struct {
+		name           string
+		token          string
+		received       string
+		secretOverride string
+		wantErr        bool
+	}{
+		{name: "good token", token: "s3cret", received: "s3cret"},
+		{name: "bad token", token: "s3cret", received: "wrong", wantErr: true},
+		{name: "missing token query param", token: "s3cret", received: "", wantErr: true},
+		{
+			name:           "secretOverride takes precedence over configured token",
+			token:          "s3cret",
+			received:       "override",
+			secretOverride: "override",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &BitbucketProvider{token: tt.token}
+
+			url := "/webhook/bitbucket"
+			if tt.received != "" {
+				url += "?token=" + tt.received
+			}
+			r := httptest.NewRequest(http.MethodPost, url, nil)
+
+			err := p.Verify(r, nil, tt.secretOverride)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBitbucketProviderParseBranchPush(t *testing.T) {
+	body := `{
+		"push": {"changes": [{"new": {"name": "main", "type": "branch", "target": {"hash": "abc123"}}}]},
+		"repository": {"name": "myrepo"},
+		"actor": {"username": "alice"}
+	}`
+
+	headers := http.Header{}
+	headers.Set("X-Event-Key", "repo:push")
+
+	p := &BitbucketProvider{}
+	event, err := p.Parse([]byte(body), headers)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if event.Ref != "refs/heads/main" {
+		t.Errorf("Ref = %q, want refs/heads/main", event.Ref)
+	}
+	if event.Repository != "myrepo" || event.CommitSHA != "abc123" || event.Pusher != "alice" {
+		t.Errorf("Parse() = %+v, unexpected fields", event)
+	}
+}
+
+func TestBitbucketProviderParseTagPush(t *testing.T) {
+	body := `{
+		"push": {"changes": [{"new": {"name": "v1.0.0", "type": "tag", "target": {"hash": "abc123"}}}]},
+		"repository": {"name": "myrepo"},
+		"actor": {"username": "alice"}
+	}`
+
+	headers := http.Header{}
+	headers.Set("X-Event-Key", "repo:push")
+
+	p := &BitbucketProvider{}
+	event, err := p.Parse([]byte(body), headers)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if event.Ref != "refs/tags/v1.0.0" {
+		t.Errorf("Ref = %q, want refs/tags/v1.0.0", event.Ref)
+	}
+}
+
+func TestBitbucketProviderParseIgnoresNonPushEvents(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("X-Event-Key", "repo:fork")
+
+	p := &BitbucketProvider{}
+	if _, err := p.Parse([]byte(`{}`), headers); err == nil {
+		t.Errorf("Parse() error = nil, want error for non-push event")
+	}
+}
+
+func TestBitbucketProviderParseNoChanges(t *testing.T) {
+	body := `{"push": {"changes": []}, "repository": {"name": "myrepo"}, "actor": {"username": "alice"}}`
+
+	headers := http.Header{}
+	headers.Set("X-Event-Key", "repo:push")
+
+	p := &BitbucketProvider{}
+	if _, err := p.Parse([]byte(body), headers); err == nil {
+		t.Errorf("Parse() error = nil, want error for push event with no changes")
+	}
+}
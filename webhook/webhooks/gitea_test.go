@@ -0,0 +1,87 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func giteaSign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestGiteaProviderVerify(t *testing.T) {
+	body := `{"ref":"refs/heads/main"}`
+
+	tests := []struct {
+		name           string
+		secret         string
+		signature      string
+		secretOverride string
+		wantErr        bool
+	}{
+		{name: "good signature", secret: "s3cret", signature: giteaSign("s3cret", body)},
+		{name: "bad signature", secret: "s3cret", signature: hex.EncodeToString([]byte("not-a-real-mac")), wantErr: true},
+		{name: "wrong secret", secret: "other", signature: giteaSign("s3cret", body), wantErr: true},
+		{name: "missing signature header", secret: "s3cret", signature: "", wantErr: true},
+		{
+			name:           "secretOverride takes precedence over configured secret",
+			secret:         "s3cret",
+			signature:      giteaSign("override", body),
+			secretOverride: "override",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &GiteaProvider{secret: tt.secret}
+
+			r := httptest.NewRequest(http.MethodPost, "/webhook/gitea", nil)
+			if tt.signature != "" {
+				r.Header.Set("X-Gitea-Signature", tt.signature)
+			}
+
+			err := p.Verify(r, []byte(body), tt.secretOverride)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGiteaProviderParse(t *testing.T) {
+	body := `{
+		"ref": "refs/heads/main",
+		"after": "abc123",
+		"repository": {"name": "myrepo"},
+		"pusher": {"username": "alice"}
+	}`
+
+	headers := http.Header{}
+	headers.Set("X-Gitea-Event", "push")
+
+	p := &GiteaProvider{}
+	event, err := p.Parse([]byte(body), headers)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if event.Repository != "myrepo" || event.Ref != "refs/heads/main" || event.CommitSHA != "abc123" || event.Pusher != "alice" {
+		t.Errorf("Parse() = %+v, unexpected fields", event)
+	}
+}
+
+func TestGiteaProviderParseIgnoresNonPushEvents(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("X-Gitea-Event", "ping")
+
+	p := &GiteaProvider{}
+	if _, err := p.Parse([]byte(`{}`), headers); err == nil {
+		t.Errorf("Parse() error = nil, want error for non-push event")
+	}
+}
@@ -0,0 +1,122 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// GitHubProvider verifies and parses push events from GitHub's webhook
+// deliveries, authenticated via the X-Hub-Signature-256 HMAC-SHA256
+// signature.
+type GitHubProvider struct {
+	secrets [][]byte
+}
+
+type githubPushPayload struct {
+	Ref        string `json:"ref"`
+	After      string `json:"after"`
+	Repository struct {
+		Name string `json:"name"`
+	} `json:"repository"`
+	Pusher struct {
+		Name string `json:"name"`
+	} `json:"pusher"`
+	HeadCommit struct {
+		Timestamp string `json:"timestamp"`
+	} `json:"head_commit"`
+}
+
+// NewGitHubProvider reads GITHUB_WEBHOOK_SECRET and returns a provider
+// configured with it. GITHUB_WEBHOOK_SECRET may hold a comma-separated
+// list of secrets, letting one deployer serve multiple repositories
+// that were each set up with their own GitHub webhook secret. It
+// panics if no usable secret is found, matching the repo's existing
+// fail-fast startup behavior.
+func NewGitHubProvider() *GitHubProvider {
+	secrets := ParseSecrets(os.Getenv("GITHUB_WEBHOOK_SECRET"))
+	if len(secrets) == 0 {
+		panic("GITHUB_WEBHOOK_SECRET environment variable is required")
+	}
+	return &GitHubProvider{secrets: secrets}
+}
+
+// ParseSecrets splits a comma-separated list of webhook secrets,
+// trimming surrounding quotes/whitespace from each entry and
+// discarding empty entries.
+func ParseSecrets(envValue string) [][]byte {
+	var secrets [][]byte
+	for _, entry := range strings.Split(envValue, ",") {
+		entry = strings.Trim(entry, "' \t\n\r")
+		if entry == "" {
+			continue
+		}
+		secrets = append(secrets, []byte(entry))
+	}
+	return secrets
+}
+
+func (p *GitHubProvider) Name() string {
+	return "github"
+}
+
+func (p *GitHubProvider) Verify(r *http.Request, body []byte, secretOverride string) error {
+	signature := r.Header.Get("X-Hub-Signature-256")
+	if signature == "" || len(signature) < 7 || signature[:7] != "sha256=" {
+		return fmt.Errorf("missing or invalid signature")
+	}
+	receivedMAC := []byte(signature[7:])
+
+	secrets := p.secrets
+	if secretOverride != "" {
+		secrets = ParseSecrets(secretOverride)
+	}
+
+	for _, secret := range secrets {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(body)
+		expectedMAC := []byte(hex.EncodeToString(mac.Sum(nil)))
+
+		if hmac.Equal(expectedMAC, receivedMAC) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("signature mismatch")
+}
+
+func (p *GitHubProvider) Parse(body []byte, headers http.Header) (*PushEvent, error) {
+	if event := headers.Get("X-GitHub-Event"); event != "push" {
+		return nil, fmt.Errorf("ignored event type: %s", event)
+	}
+
+	var payload githubPushPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	// head_commit.timestamp is absent on branch deletions/force-pushes
+	// with no new commits; leave Timestamp zero in that case.
+	timestamp, _ := time.Parse(time.RFC3339, payload.HeadCommit.Timestamp)
+
+	return &PushEvent{
+		Provider:   p.Name(),
+		Repository: payload.Repository.Name,
+		Ref:        payload.Ref,
+		CommitSHA:  payload.After,
+		Pusher:     payload.Pusher.Name,
+		Timestamp:  timestamp,
+	}, nil
+}
+
+// DeliveryID returns GitHub's per-delivery UUID, used to deduplicate
+// retried webhook deliveries.
+func (p *GitHubProvider) DeliveryID(headers http.Header) string {
+	return headers.Get("X-GitHub-Delivery")
+}
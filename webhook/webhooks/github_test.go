@@ -0,0 +1,121 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseSecrets(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want [][]byte
+	}{
+		{name: "single secret", in: "s3cret", want: [][]byte{[]byte("s3cret")}},
+		{name: "comma-separated list", in: "one,two,three", want: [][]byte{[]byte("one"), []byte("two"), []byte("three")}},
+		{name: "trims quotes and whitespace", in: " 'one' , 'two' ", want: [][]byte{[]byte("one"), []byte("two")}},
+		{name: "drops empty entries", in: "one,,two,", want: [][]byte{[]byte("one"), []byte("two")}},
+		{name: "empty input", in: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseSecrets(tt.in)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSecrets(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func githubSign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestGitHubProviderVerify(t *testing.T) {
+	body := `{"ref":"refs/heads/main"}`
+
+	tests := []struct {
+		name           string
+		secrets        string
+		signature      string
+		secretOverride string
+		wantErr        bool
+	}{
+		{name: "good signature", secrets: "s3cret", signature: githubSign("s3cret", body)},
+		{name: "bad signature", secrets: "s3cret", signature: "sha256=" + hex.EncodeToString([]byte("not-a-real-mac-00000000000000000")), wantErr: true},
+		{name: "wrong secret", secrets: "other", signature: githubSign("s3cret", body), wantErr: true},
+		{name: "missing signature header", secrets: "s3cret", signature: "", wantErr: true},
+		{name: "missing sha256 prefix", secrets: "s3cret", signature: hex.EncodeToString([]byte("abc")), wantErr: true},
+		{
+			name:           "matches secret from comma-separated list",
+			secrets:        "first,s3cret,third",
+			signature:      githubSign("s3cret", body),
+		},
+		{
+			name:           "secretOverride takes precedence over configured secrets",
+			secrets:        "s3cret",
+			signature:      githubSign("override", body),
+			secretOverride: "override",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &GitHubProvider{secrets: ParseSecrets(tt.secrets)}
+
+			r := httptest.NewRequest(http.MethodPost, "/webhook/github", nil)
+			if tt.signature != "" {
+				r.Header.Set("X-Hub-Signature-256", tt.signature)
+			}
+
+			err := p.Verify(r, []byte(body), tt.secretOverride)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGitHubProviderParse(t *testing.T) {
+	body := `{
+		"ref": "refs/heads/main",
+		"after": "abc123",
+		"repository": {"name": "myrepo"},
+		"pusher": {"name": "alice"},
+		"head_commit": {"timestamp": "2024-01-02T15:04:05Z"}
+	}`
+
+	headers := http.Header{}
+	headers.Set("X-GitHub-Event", "push")
+
+	p := &GitHubProvider{}
+	event, err := p.Parse([]byte(body), headers)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if event.Repository != "myrepo" || event.Ref != "refs/heads/main" || event.CommitSHA != "abc123" || event.Pusher != "alice" {
+		t.Errorf("Parse() = %+v, unexpected fields", event)
+	}
+	if event.Timestamp.IsZero() {
+		t.Errorf("Parse() left Timestamp zero, want decoded head_commit.timestamp")
+	}
+}
+
+func TestGitHubProviderParseIgnoresNonPushEvents(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("X-GitHub-Event", "ping")
+
+	p := &GitHubProvider{}
+	if _, err := p.Parse([]byte(`{}`), headers); err == nil {
+		t.Errorf("Parse() error = nil, want error for non-push event")
+	}
+}
@@ -0,0 +1,75 @@
+package webhooks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGitLabProviderVerify(t *testing.T) {
+	tests := []struct {
+		name           string
+		token          string
+		received       string
+		secretOverride string
+		wantErr        bool
+	}{
+		{name: "good token", token: "s3cret", received: "s3cret"},
+		{name: "bad token", token: "s3cret", received: "wrong", wantErr: true},
+		{name: "missing token header", token: "s3cret", received: "", wantErr: true},
+		{
+			name:           "secretOverride takes precedence over configured token",
+			token:          "s3cret",
+			received:       "override",
+			secretOverride: "override",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &GitLabProvider{token: tt.token}
+
+			r := httptest.NewRequest(http.MethodPost, "/webhook/gitlab", nil)
+			if tt.received != "" {
+				r.Header.Set("X-Gitlab-Token", tt.received)
+			}
+
+			err := p.Verify(r, nil, tt.secretOverride)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGitLabProviderParse(t *testing.T) {
+	body := `{
+		"ref": "refs/heads/main",
+		"after": "abc123",
+		"project": {"name": "myrepo"},
+		"user_username": "alice"
+	}`
+
+	headers := http.Header{}
+	headers.Set("X-Gitlab-Event", "Push Hook")
+
+	p := &GitLabProvider{}
+	event, err := p.Parse([]byte(body), headers)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if event.Repository != "myrepo" || event.Ref != "refs/heads/main" || event.CommitSHA != "abc123" || event.Pusher != "alice" {
+		t.Errorf("Parse() = %+v, unexpected fields", event)
+	}
+}
+
+func TestGitLabProviderParseIgnoresNonPushEvents(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("X-Gitlab-Event", "Tag Push Hook")
+
+	p := &GitLabProvider{}
+	if _, err := p.Parse([]byte(`{}`), headers); err == nil {
+		t.Errorf("Parse() error = nil, want error for non-push event")
+	}
+}
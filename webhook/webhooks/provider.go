@@ -0,0 +1,79 @@
+// Package webhooks defines the pluggable provider interface used to
+// verify and parse push notifications from different git hosting
+// services (GitHub, Gitea, GitLab, Bitbucket, ...).
+package webhooks
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// PushEvent is the normalized representation of a push notification,
+// independent of which provider sent it. Downstream deploy logic only
+// ever sees this type.
+type PushEvent struct {
+	Provider   string
+	Repository string
+	Ref        string
+	CommitSHA  string
+	Pusher     string
+
+	// Timestamp is when the pushed commit was made, as reported by the
+	// provider. It's used to bound clock skew and reject replay of
+	// captured-but-valid signed bodies. Zero if the provider doesn't
+	// report one.
+	Timestamp time.Time
+}
+
+// Provider is implemented by each supported git hosting service.
+type Provider interface {
+	// Name returns the provider identifier used in the /webhook/{provider}
+	// route and in logs (e.g. "github", "gitea", "gitlab", "bitbucket").
+	Name() string
+
+	// Verify checks that the request genuinely originated from this
+	// provider, typically via a signature or token header. secretOverride,
+	// if non-empty, is used instead of the provider's configured secret
+	// (e.g. a per-repository secret from the deploy config).
+	Verify(r *http.Request, body []byte, secretOverride string) error
+
+	// Parse extracts a normalized PushEvent from the raw body. It
+	// returns an error if the payload isn't a push event the provider
+	// recognizes or can't be decoded.
+	Parse(body []byte, headers http.Header) (*PushEvent, error)
+
+	// DeliveryID returns the provider's unique identifier for this
+	// delivery attempt (e.g. GitHub's X-GitHub-Delivery), used for
+	// deduplicating retried deliveries. Returns "" if the provider
+	// doesn't send one.
+	DeliveryID(headers http.Header) string
+}
+
+var providers = map[string]Provider{}
+
+// Register makes a provider available under its Name() for routing and
+// lookup. It panics on duplicate registration, mirroring the standard
+// library's database/sql driver registry.
+func Register(p Provider) {
+	name := p.Name()
+	if _, exists := providers[name]; exists {
+		panic(fmt.Sprintf("webhooks: provider %q already registered", name))
+	}
+	providers[name] = p
+}
+
+// Get returns the provider registered under name, if any.
+func Get(name string) (Provider, bool) {
+	p, ok := providers[name]
+	return p, ok
+}
+
+// Names returns the identifiers of all registered providers.
+func Names() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,64 @@
+package webhooks
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+type fakeProvider struct{ name string }
+
+func (f *fakeProvider) Name() string { return f.name }
+func (f *fakeProvider) Verify(r *http.Request, body []byte, secretOverride string) error {
+	return nil
+}
+func (f *fakeProvider) Parse(body []byte, headers http.Header) (*PushEvent, error) { return nil, nil }
+func (f *fakeProvider) DeliveryID(headers http.Header) string                      { return "" }
+
+func TestRegisterAndGet(t *testing.T) {
+	defer func(saved map[string]Provider) { providers = saved }(providers)
+	providers = map[string]Provider{}
+
+	p := &fakeProvider{name: "fake"}
+	Register(p)
+
+	got, ok := Get("fake")
+	if !ok || got != Provider(p) {
+		t.Errorf("Get(%q) = %v, %v; want %v, true", "fake", got, ok, p)
+	}
+
+	if _, ok := Get("missing"); ok {
+		t.Errorf("Get(%q) ok = true, want false", "missing")
+	}
+}
+
+func TestRegisterPanicsOnDuplicate(t *testing.T) {
+	defer func(saved map[string]Provider) { providers = saved }(providers)
+	providers = map[string]Provider{}
+
+	Register(&fakeProvider{name: "fake"})
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Register() did not panic on duplicate registration")
+		}
+	}()
+	Register(&fakeProvider{name: "fake"})
+}
+
+func TestNames(t *testing.T) {
+	defer func(saved map[string]Provider) { providers = saved }(providers)
+	providers = map[string]Provider{}
+
+	Register(&fakeProvider{name: "a"})
+	Register(&fakeProvider{name: "b"})
+
+	got := append([]string(nil), Names()...)
+	sort.Strings(got)
+
+	want := []string{"a", "b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+}